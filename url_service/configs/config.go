@@ -9,8 +9,9 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Server   ServerConfig   `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Server     ServerConfig     `yaml:"server"`
+	Resilience ResilienceConfig `yaml:"resilience"`
 }
 
 type DatabaseConfig struct {
@@ -27,8 +28,54 @@ type DatabaseConfig struct {
 type ServerConfig struct {
 	Port int    `yaml:"port"`
 	Host string `yaml:"host"`
+
+	// WebsocketMaxFrameBytes bounds the response buffer grpc-websocket-proxy
+	// uses when relaying streaming RPCs (e.g. WatchClicks) over the gateway's
+	// WebSocket endpoint. Defaults to 1 MiB when unset so a single event's
+	// JSON frame isn't truncated by the proxy's default 32-64 KiB chunking.
+	WebsocketMaxFrameBytes int `yaml:"websocket_max_frame_bytes"`
+
+	// TLSCertDir and TLSKeyDir hold a directory of <server_name>.crt /
+	// <server_name>.key pairs, one per short-domain hostname, watched for
+	// hot reload. Ignored when SelfSigned is true.
+	TLSCertDir string `yaml:"tls_cert_dir"`
+	TLSKeyDir  string `yaml:"tls_key_dir"`
+	// SelfSigned generates an in-memory CA and leaf certificate at startup
+	// instead of loading TLSCertDir/TLSKeyDir, for local development.
+	SelfSigned bool `yaml:"self_signed"`
+
+	// ShutdownTimeoutSeconds bounds how long each component gets to stop
+	// gracefully during shutdown.
+	ShutdownTimeoutSeconds int `yaml:"shutdown_timeout_seconds"`
+
+	// AdminPort is the listener UrlAdminService is served on, separate from
+	// the public Port so it can be firewalled to the admin network.
+	AdminPort int `yaml:"admin_port"`
+	// AdminToken is the shared-secret bearer token UrlAdminService requires.
+	// Overridden by the ADMIN_TOKEN environment variable.
+	AdminToken string `yaml:"admin_token"`
+}
+
+// ResilienceConfig controls the retry/circuit-breaker decorator wrapped
+// around the URL repository. MaxRetries of 0 disables retrying.
+type ResilienceConfig struct {
+	MaxRetries             int `yaml:"max_retries"`
+	BaseDelayMs            int `yaml:"base_delay_ms"`
+	MaxDelayMs             int `yaml:"max_delay_ms"`
+	CircuitThreshold       int `yaml:"circuit_threshold"`
+	CircuitCooldownSeconds int `yaml:"circuit_cooldown_seconds"`
 }
 
+const defaultWebsocketMaxFrameBytes = 1 << 20 // 1 MiB
+
+const (
+	defaultRetryMaxRetries  = 3
+	defaultRetryBaseDelayMs = 50
+	defaultRetryMaxDelayMs  = 2000
+)
+
+const defaultShutdownTimeoutSeconds = 30
+
 // LoadConfig loads configuration from a YAML file and overrides with environment variables
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -41,6 +88,17 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	// max_retries: 0 is a documented way to disable retrying, so it must be
+	// distinguished from "not set in the file" (which also unmarshals to the
+	// int zero value) before defaulting below.
+	maxRetriesSet := false
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err == nil {
+		if resilience, ok := raw["resilience"]; ok {
+			_, maxRetriesSet = resilience["max_retries"]
+		}
+	}
+
 	// Override with environment variables if set
 	if host := os.Getenv("DB_HOST"); host != "" {
 		config.Database.Host = host
@@ -59,6 +117,32 @@ func LoadConfig(path string) (*Config, error) {
 	if dbname := os.Getenv("DB_NAME"); dbname != "" {
 		config.Database.DBName = dbname
 	}
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		config.Server.AdminToken = adminToken
+	}
+
+	if config.Server.WebsocketMaxFrameBytes <= 0 {
+		config.Server.WebsocketMaxFrameBytes = defaultWebsocketMaxFrameBytes
+	}
+
+	if !maxRetriesSet {
+		config.Resilience.MaxRetries = defaultRetryMaxRetries
+	}
+	if config.Resilience.BaseDelayMs <= 0 {
+		config.Resilience.BaseDelayMs = defaultRetryBaseDelayMs
+	}
+	if config.Resilience.MaxDelayMs <= 0 {
+		config.Resilience.MaxDelayMs = defaultRetryMaxDelayMs
+	}
+
+	if config.Server.ShutdownTimeoutSeconds <= 0 {
+		config.Server.ShutdownTimeoutSeconds = defaultShutdownTimeoutSeconds
+	}
+
+	if config.Server.AdminPort <= 0 {
+		// Port and Port+1 are taken by the public gRPC/HTTP listeners.
+		config.Server.AdminPort = config.Server.Port + 2
+	}
 
 	return &config, nil
 }
@@ -0,0 +1,75 @@
+// Package app provides a small lifecycle manager that starts and stops a
+// fixed set of components in order, so new subsystems (websocket analytics,
+// a metrics exporter, an admin API, ...) can be added without further
+// bloating main.go.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"log/slog"
+)
+
+// Component is anything the Manager can start and stop as a unit: a storage
+// layer, a gRPC server, an HTTP gateway, a certificate watcher, and so on.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Manager starts components in registration order and stops them in reverse
+// order, giving each a bounded shutdownTimeout to finish.
+type Manager struct {
+	components      []Component
+	shutdownTimeout time.Duration
+}
+
+// NewManager creates a Manager that allows each component up to
+// shutdownTimeout to stop gracefully.
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	return &Manager{shutdownTimeout: shutdownTimeout}
+}
+
+// Register appends a component to the startup/shutdown order.
+func (m *Manager) Register(c Component) {
+	m.components = append(m.components, c)
+}
+
+// Start starts every registered component in order. If one fails, every
+// component started before it is stopped (in reverse order) before returning
+// the error.
+func (m *Manager) Start(ctx context.Context) error {
+	for i, c := range m.components {
+		slog.Info("starting component", "name", c.Name())
+		if err := c.Start(ctx); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				_ = m.components[j].Stop(ctx)
+			}
+			return fmt.Errorf("failed to start %s: %w", c.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Stop stops every registered component in reverse order, giving each up to
+// shutdownTimeout. It continues through every component even if one fails to
+// stop cleanly, and returns the combined error.
+func (m *Manager) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(m.components) - 1; i >= 0; i-- {
+		c := m.components[i]
+		stopCtx, cancel := context.WithTimeout(ctx, m.shutdownTimeout)
+		if err := c.Stop(stopCtx); err != nil {
+			slog.Error("error stopping component", "name", c.Name(), "err", err)
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name(), err))
+		} else {
+			slog.Info("stopped component", "name", c.Name())
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
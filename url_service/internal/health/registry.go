@@ -0,0 +1,59 @@
+// Package health backs the shared /healthz and /readyz endpoints that
+// internal/app components register their readiness into.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Registry tracks a readiness check per named component.
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]func() bool
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]func() bool)}
+}
+
+// Register installs a readiness check for a component. check is called on
+// every /readyz request, so it must be cheap and non-blocking.
+func (r *Registry) Register(name string, check func() bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Healthz reports liveness: the process is up and able to handle HTTP
+// requests, regardless of whether every component is ready yet.
+func (r *Registry) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// Readyz reports readiness: 200 only if every registered component's check
+// currently passes, 503 otherwise, with a per-component breakdown.
+func (r *Registry) Readyz(w http.ResponseWriter, _ *http.Request) {
+	r.mu.RLock()
+	statuses := make(map[string]bool, len(r.checks))
+	allReady := true
+	for name, check := range r.checks {
+		ready := check()
+		statuses[name] = ready
+		if !ready {
+			allReady = false
+		}
+	}
+	r.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if allReady {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(statuses)
+}
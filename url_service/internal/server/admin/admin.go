@@ -0,0 +1,116 @@
+// Package admin serves UrlAdminService on its own gRPC listener and HTTP
+// gateway (Server.AdminPort / AdminPort+1), separate from the public
+// UrlService listener, so the admin surface can be firewalled independently
+// and is guarded by a shared-secret bearer token on every call.
+package admin
+
+import (
+	"context"
+	stdtls "crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"log/slog"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/my_projects/url_service/configs"
+	"github.com/my_projects/url_service/gen"
+	"github.com/my_projects/url_service/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server is the admin gRPC server plus its HTTP gateway.
+type Server struct {
+	cfg          configs.ServerConfig
+	tlsConfig    *stdtls.Config
+	adminService gen.UrlAdminServiceServer
+
+	grpcServer *grpc.Server
+	listener   net.Listener
+	httpServer *http.Server
+}
+
+// New creates an admin Server component. cfg.AdminPort/AdminToken must be
+// set for the server to be reachable and authenticated.
+func New(cfg configs.ServerConfig, tlsConfig *stdtls.Config, adminService gen.UrlAdminServiceServer) *Server {
+	return &Server{cfg: cfg, tlsConfig: tlsConfig, adminService: adminService}
+}
+
+func (s *Server) Name() string { return "admin-server" }
+
+func (s *Server) grpcAddr() string {
+	return fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.AdminPort)
+}
+
+func (s *Server) httpAddr() string {
+	return fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.AdminPort+1)
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.grpcAddr())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.grpcAddr(), err)
+	}
+	s.listener = listener
+
+	s.grpcServer = grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(s.tlsConfig)),
+		grpc.UnaryInterceptor(service.AdminAuthInterceptor(s.cfg.AdminToken)),
+	)
+	gen.RegisterUrlAdminServiceServer(s.grpcServer, s.adminService)
+
+	go func() {
+		slog.Info("starting admin gRPC server", "addr", s.grpcAddr())
+		if err := s.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			slog.Error("admin gRPC server stopped unexpectedly", "err", err)
+		}
+	}()
+
+	gwmux := runtime.NewServeMux(runtime.WithErrorHandler(service.GatewayErrorHandler))
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&stdtls.Config{InsecureSkipVerify: true}))}
+	if err := gen.RegisterUrlAdminServiceHandlerFromEndpoint(ctx, gwmux, s.grpcAddr(), dialOpts); err != nil {
+		return fmt.Errorf("failed to register admin gateway: %w", err)
+	}
+
+	s.httpServer = &http.Server{
+		Addr:      s.httpAddr(),
+		Handler:   gwmux,
+		TLSConfig: s.tlsConfig,
+	}
+
+	go func() {
+		slog.Info("starting admin HTTPS gateway", "addr", s.httpAddr())
+		if err := s.httpServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			slog.Error("admin gateway stopped unexpectedly", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		slog.Error("error stopping admin gateway", "err", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// Ready reports whether the admin server has an active listener.
+func (s *Server) Ready() bool {
+	return s.listener != nil
+}
@@ -0,0 +1,80 @@
+// Package grpc wraps the public gRPC listener as an internal/app.Component.
+package grpc
+
+import (
+	"context"
+	stdtls "crypto/tls"
+	"fmt"
+	"net"
+
+	"log/slog"
+
+	"github.com/my_projects/url_service/configs"
+	"github.com/my_projects/url_service/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server is the public gRPC listener the URL service is registered on.
+type Server struct {
+	cfg        configs.ServerConfig
+	tlsConfig  *stdtls.Config
+	urlService gen.UrlServiceServer
+	grpcServer *grpc.Server
+	listener   net.Listener
+}
+
+// New creates a gRPC Server component listening on cfg.Host:cfg.Port and
+// serving urlService over TLS using tlsConfig's GetCertificate callback.
+func New(cfg configs.ServerConfig, tlsConfig *stdtls.Config, urlService gen.UrlServiceServer) *Server {
+	return &Server{cfg: cfg, tlsConfig: tlsConfig, urlService: urlService}
+}
+
+func (s *Server) Name() string { return "grpc-server" }
+
+// Addr returns the configured address (cfg.Host:cfg.Port) the server listens
+// on. It is derived from the config alone, so it is usable before Start.
+func (s *Server) Addr() string {
+	return fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+}
+
+func (s *Server) Start(_ context.Context) error {
+	listener, err := net.Listen("tcp", s.Addr())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr(), err)
+	}
+	s.listener = listener
+
+	s.grpcServer = grpc.NewServer(grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	gen.RegisterUrlServiceServer(s.grpcServer, s.urlService)
+
+	go func() {
+		slog.Info("starting gRPC server", "addr", s.Addr())
+		if err := s.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			slog.Error("gRPC server stopped unexpectedly", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// Ready reports whether the server has an active listener.
+func (s *Server) Ready() bool {
+	return s.listener != nil
+}
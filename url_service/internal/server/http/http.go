@@ -0,0 +1,111 @@
+// Package http wraps the grpc-gateway HTTP mux, including the WatchClicks
+// WebSocket bridge and the shared /healthz and /readyz endpoints, as an
+// internal/app.Component.
+package http
+
+import (
+	"context"
+	stdtls "crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/my_projects/url_service/configs"
+	"github.com/my_projects/url_service/gen"
+	"github.com/my_projects/url_service/internal/health"
+	"github.com/my_projects/url_service/service"
+	"github.com/tmc/grpc-websocket-proxy/wsproxy"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// clientAddrMetadataKey carries the browser's address through to the
+// backend as ordinary gRPC metadata. peer.FromContext on the backend would
+// instead see this gateway's own loopback dial, so WatchClicks's ip_hash
+// relies on this annotator rather than the gRPC peer.
+const clientAddrMetadataKey = "x-client-addr"
+
+// clientAddrAnnotator extracts the real client address from the incoming
+// HTTP request (preferring a forwarded-for chain ahead of a direct
+// RemoteAddr) so service.ipHashFromContext hashes the browser, not the
+// gateway's loopback connection.
+func clientAddrAnnotator(_ context.Context, r *http.Request) metadata.MD {
+	addr := r.RemoteAddr
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		if first := strings.TrimSpace(strings.Split(forwarded, ",")[0]); first != "" {
+			addr = first
+		}
+	}
+	if addr == "" {
+		return nil
+	}
+	return metadata.Pairs(clientAddrMetadataKey, addr)
+}
+
+// Server is the HTTP gateway in front of the gRPC server.
+type Server struct {
+	cfg        configs.ServerConfig
+	tlsConfig  *stdtls.Config
+	grpcAddr   string
+	health     *health.Registry
+	httpServer *http.Server
+}
+
+// New creates an HTTP gateway Server that dials grpcAddr and serves on
+// cfg.Host:cfg.Port+1, registering its own readiness into health.
+func New(cfg configs.ServerConfig, tlsConfig *stdtls.Config, grpcAddr string, health *health.Registry) *Server {
+	return &Server{cfg: cfg, tlsConfig: tlsConfig, grpcAddr: grpcAddr, health: health}
+}
+
+func (s *Server) Name() string { return "http-gateway" }
+
+func (s *Server) addr() string {
+	return fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port+1)
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	gwmux := runtime.NewServeMux(
+		runtime.WithErrorHandler(service.GatewayErrorHandler),
+		runtime.WithMetadata(clientAddrAnnotator),
+	)
+
+	// The gateway dials the gRPC server over loopback using the same
+	// certificate manager; skip verification since, for self-signed mode,
+	// there is no external CA the client could validate against.
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&stdtls.Config{InsecureSkipVerify: true}))}
+	if err := gen.RegisterUrlServiceHandlerFromEndpoint(ctx, gwmux, s.grpcAddr, dialOpts); err != nil {
+		return fmt.Errorf("failed to register gateway: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.health.Healthz)
+	mux.HandleFunc("/readyz", s.health.Readyz)
+	mux.Handle("/", wsproxy.WebsocketProxy(gwmux,
+		wsproxy.WithMaxRespBodyBufferSize(s.cfg.WebsocketMaxFrameBytes),
+	))
+
+	s.httpServer = &http.Server{
+		Addr:      s.addr(),
+		Handler:   mux,
+		TLSConfig: s.tlsConfig,
+	}
+
+	go func() {
+		slog.Info("starting HTTPS gateway server", "addr", s.addr())
+		if err := s.httpServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			slog.Error("HTTP gateway server stopped unexpectedly", "err", err)
+		}
+	}()
+
+	s.health.Register(s.Name(), func() bool { return s.httpServer != nil })
+
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,105 @@
+// Package storage owns the pgxpool lifecycle and schema migrations, exposed
+// as an internal/app.Component so it starts before anything that depends on
+// it and is the last thing to shut down.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"log/slog"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/my_projects/url_service/configs"
+)
+
+// migrationsPath is the directory of golang-migrate SQL files applied on
+// startup, relative to the process's working directory.
+const migrationsPath = "file://migrations"
+
+// Storage manages the database connection pool and applies pending
+// migrations before the pool is considered ready.
+type Storage struct {
+	cfg  configs.DatabaseConfig
+	pool *pgxpool.Pool
+}
+
+// New creates a Storage component for the given database configuration.
+func New(cfg configs.DatabaseConfig) *Storage {
+	return &Storage{cfg: cfg}
+}
+
+func (s *Storage) Name() string { return "storage" }
+
+// Start opens the connection pool, pings it, and applies any pending
+// migrations. It is idempotent: a second call is a no-op once the pool is
+// open, since the gRPC/HTTP components need the pool before they themselves
+// are registered and started.
+func (s *Storage) Start(ctx context.Context) error {
+	if s.pool != nil {
+		return nil
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(s.cfg.GetDatabaseURL())
+	if err != nil {
+		return fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	poolConfig.MaxConns = int32(s.cfg.MaxConnections)
+	poolConfig.MinConns = int32(s.cfg.MaxIdleConnections)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create database pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if err := runMigrations(s.cfg.GetDatabaseURL()); err != nil {
+		pool.Close()
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	s.pool = pool
+	slog.Info("database ready", "host", s.cfg.Host, "dbname", s.cfg.DBName)
+	return nil
+}
+
+// Stop closes the connection pool.
+func (s *Storage) Stop(_ context.Context) error {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+	return nil
+}
+
+// Ready reports whether the pool has been opened successfully.
+func (s *Storage) Ready() bool {
+	return s.pool != nil
+}
+
+// Pool returns the underlying connection pool. It is only valid after Start
+// has returned successfully.
+func (s *Storage) Pool() *pgxpool.Pool {
+	return s.pool
+}
+
+func runMigrations(dbURL string) error {
+	m, err := migrate.New(migrationsPath, dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
@@ -3,10 +3,17 @@ package model
 import "time"
 
 type Url struct {
-	Url            string    `json:"url"`
-	UrlId          string    `json:"url_id"`
-	ShortUrl       string    `json:"short_url"`
-	CreatedAt      time.Time `json:"created"`
-	ClickCount     int64     `json:"click_count"`
-	LastAccessedAt time.Time `json:"last_accessed_at"`
+	Url            string     `json:"url"`
+	UrlId          string     `json:"url_id"`
+	ShortUrl       string     `json:"short_url"`
+	CreatedAt      time.Time  `json:"created"`
+	ClickCount     int64      `json:"click_count"`
+	LastAccessedAt time.Time  `json:"last_accessed_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+// AccessRecord is one entry in a short URL's recent access log.
+type AccessRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Referer   string    `json:"referer,omitempty"`
 }
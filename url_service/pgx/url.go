@@ -2,9 +2,11 @@ package pgx
 
 import (
 	"context"
+	"time"
 
 	"log/slog"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/my_projects/url_service/model"
 	repository "github.com/my_projects/url_service/repository/intf"
@@ -69,7 +71,7 @@ func (r *UrlRepository) Save(ctx context.Context, url *model.Url) error {
 // GetByUrl finds a URL record by its original URL
 func (r *UrlRepository) GetByUrl(ctx context.Context, originalUrl string) (*model.Url, error) {
 	query := `
-		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at
+		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at, expires_at
 		FROM url
 		WHERE url = $1
 		LIMIT 1
@@ -83,6 +85,7 @@ func (r *UrlRepository) GetByUrl(ctx context.Context, originalUrl string) (*mode
 		&url.CreatedAt,
 		&url.ClickCount,
 		&url.LastAccessedAt,
+		&url.ExpiresAt,
 	)
 	if err != nil {
 		slog.Error("error while finding url by original url", "err", err, "url", originalUrl)
@@ -94,8 +97,29 @@ func (r *UrlRepository) GetByUrl(ctx context.Context, originalUrl string) (*mode
 
 // GetByShortUrl finds a URL record by its short URL
 func (r *UrlRepository) GetByShortUrl(ctx context.Context, shortUrl string) (*model.Url, error) {
+	url, err := r.getByShortUrl(ctx, shortUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		// An expired link resolves the same as one that was never created.
+		return nil, pgx.ErrNoRows
+	}
+
+	return url, nil
+}
+
+// GetByShortUrlIgnoringExpiry finds a URL record by its short URL without
+// applying the expiry filter GetByShortUrl does, for admin reads that need
+// the row even once it has stopped resolving.
+func (r *UrlRepository) GetByShortUrlIgnoringExpiry(ctx context.Context, shortUrl string) (*model.Url, error) {
+	return r.getByShortUrl(ctx, shortUrl)
+}
+
+func (r *UrlRepository) getByShortUrl(ctx context.Context, shortUrl string) (*model.Url, error) {
 	query := `
-		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at
+		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at, expires_at
 		FROM url
 		WHERE short_url = $1
 		LIMIT 1
@@ -109,6 +133,7 @@ func (r *UrlRepository) GetByShortUrl(ctx context.Context, shortUrl string) (*mo
 		&url.CreatedAt,
 		&url.ClickCount,
 		&url.LastAccessedAt,
+		&url.ExpiresAt,
 	)
 	if err != nil {
 		slog.Error("error while finding url by short url", "err", err, "short_url", shortUrl)
@@ -154,7 +179,7 @@ func (r *UrlRepository) List(ctx context.Context, offset, limit int) ([]*model.U
 
 	// Get paginated results
 	query := `
-		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at
+		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at, expires_at
 		FROM url
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -177,6 +202,7 @@ func (r *UrlRepository) List(ctx context.Context, offset, limit int) ([]*model.U
 			&url.CreatedAt,
 			&url.ClickCount,
 			&url.LastAccessedAt,
+			&url.ExpiresAt,
 		)
 		if err != nil {
 			slog.Error("error while scanning url row", "err", err)
@@ -192,3 +218,156 @@ func (r *UrlRepository) List(ctx context.Context, offset, limit int) ([]*model.U
 
 	return urls, totalCount, nil
 }
+
+// ListRecentlyAccessed returns up to limit URLs ordered by last_accessed_at
+// descending, skipping URLs that have never been accessed.
+func (r *UrlRepository) ListRecentlyAccessed(ctx context.Context, limit int) ([]*model.Url, error) {
+	query := `
+		SELECT url, url_id, short_url, created_at, click_count, last_accessed_at, expires_at
+		FROM url
+		WHERE last_accessed_at IS NOT NULL AND click_count > 0
+		ORDER BY last_accessed_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		slog.Error("error while listing recently accessed urls", "err", err, "limit", limit)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []*model.Url
+	for rows.Next() {
+		var url model.Url
+		err := rows.Scan(
+			&url.Url,
+			&url.UrlId,
+			&url.ShortUrl,
+			&url.CreatedAt,
+			&url.ClickCount,
+			&url.LastAccessedAt,
+			&url.ExpiresAt,
+		)
+		if err != nil {
+			slog.Error("error while scanning recently accessed url row", "err", err)
+			return nil, err
+		}
+		urls = append(urls, &url)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Error("error iterating recently accessed url rows", "err", err)
+		return nil, err
+	}
+
+	return urls, nil
+}
+
+// Delete removes a URL record by its short URL.
+func (r *UrlRepository) Delete(ctx context.Context, shortUrl string) error {
+	query := `DELETE FROM url WHERE short_url = $1`
+
+	result, err := r.pool.Exec(ctx, query, shortUrl)
+	if err != nil {
+		slog.Error("error while deleting url", "err", err, "short_url", shortUrl)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateShortUrl changes the short code a URL resolves from.
+func (r *UrlRepository) UpdateShortUrl(ctx context.Context, urlId, newShortUrl string) error {
+	query := `UPDATE url SET short_url = $1 WHERE url_id = $2`
+
+	result, err := r.pool.Exec(ctx, query, newShortUrl, urlId)
+	if err != nil {
+		slog.Error("error while rotating short url", "err", err, "url_id", urlId)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetExpiry sets (or, with a zero expiresAt, clears) a short URL's expiry.
+func (r *UrlRepository) SetExpiry(ctx context.Context, shortUrl string, expiresAt time.Time) error {
+	query := `UPDATE url SET expires_at = $1 WHERE short_url = $2`
+
+	var arg interface{}
+	if !expiresAt.IsZero() {
+		arg = expiresAt
+	}
+
+	result, err := r.pool.Exec(ctx, query, arg, shortUrl)
+	if err != nil {
+		slog.Error("error while setting expiry", "err", err, "short_url", shortUrl)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordAccess appends an entry to a short URL's access log.
+func (r *UrlRepository) RecordAccess(ctx context.Context, shortUrl, referer string) error {
+	query := `INSERT INTO url_access_log (short_url, accessed_at, referer) VALUES ($1, NOW(), $2)`
+
+	if _, err := r.pool.Exec(ctx, query, shortUrl, referer); err != nil {
+		slog.Error("error while recording access", "err", err, "short_url", shortUrl)
+		return err
+	}
+
+	return nil
+}
+
+// RecentAccesses returns up to limit of the most recent access log entries
+// for a short URL, newest first.
+func (r *UrlRepository) RecentAccesses(ctx context.Context, shortUrl string, limit int) ([]model.AccessRecord, error) {
+	query := `
+		SELECT accessed_at, referer
+		FROM url_access_log
+		WHERE short_url = $1
+		ORDER BY accessed_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, shortUrl, limit)
+	if err != nil {
+		slog.Error("error while listing recent accesses", "err", err, "short_url", shortUrl)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []model.AccessRecord
+	for rows.Next() {
+		var record model.AccessRecord
+		var referer *string
+		if err := rows.Scan(&record.Timestamp, &referer); err != nil {
+			slog.Error("error while scanning access record", "err", err)
+			return nil, err
+		}
+		if referer != nil {
+			record.Referer = *referer
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("error iterating access log rows", "err", err)
+		return nil, err
+	}
+
+	return records, nil
+}
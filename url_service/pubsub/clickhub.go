@@ -0,0 +1,91 @@
+// Package pubsub provides a small in-process fan-out hub used to push click
+// analytics to streaming gRPC subscribers without coupling the publisher
+// (the service layer) to how many watchers are currently attached.
+package pubsub
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/my_projects/url_service/gen"
+)
+
+// defaultBufferSize bounds how many events a slow subscriber can lag behind
+// before we start dropping its oldest buffered event.
+const defaultBufferSize = 64
+
+// ClickHub fans out ClickEvents to any number of subscribers over bounded
+// per-subscriber channels. A subscriber that falls behind has its oldest
+// buffered event dropped rather than blocking the publisher.
+type ClickHub struct {
+	mu          sync.Mutex
+	bufferSize  int
+	subscribers map[*subscription]struct{}
+}
+
+type subscription struct {
+	events  chan *gen.ClickEvent
+	dropped uint64 // accessed atomically; read via ClickHub.Subscribe's droppedCount
+}
+
+// NewClickHub creates a ClickHub whose subscriber channels hold bufferSize
+// events before dropping the oldest one. A bufferSize <= 0 falls back to
+// defaultBufferSize.
+func NewClickHub(bufferSize int) *ClickHub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &ClickHub{
+		bufferSize:  bufferSize,
+		subscribers: make(map[*subscription]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber and returns a channel of events, an
+// unsubscribe function that must be called once the caller is done reading,
+// and a droppedCount function reporting how many buffered events have been
+// dropped for this subscriber so far (because it fell behind).
+func (h *ClickHub) Subscribe() (events <-chan *gen.ClickEvent, unsubscribe func(), droppedCount func() uint64) {
+	sub := &subscription{events: make(chan *gen.ClickEvent, h.bufferSize)}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe = func() {
+		h.mu.Lock()
+		delete(h.subscribers, sub)
+		h.mu.Unlock()
+		close(sub.events)
+	}
+
+	droppedCount = func() uint64 {
+		return atomic.LoadUint64(&sub.dropped)
+	}
+
+	return sub.events, unsubscribe, droppedCount
+}
+
+// Publish fans an event out to every current subscriber. Subscribers whose
+// buffer is full have their oldest pending event dropped (and their drop
+// counter incremented) so Publish never blocks on a slow reader.
+func (h *ClickHub) Publish(event *gen.ClickEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			select {
+			case <-sub.events:
+				atomic.AddUint64(&sub.dropped, 1)
+			default:
+			}
+			select {
+			case sub.events <- event:
+			default:
+			}
+		}
+	}
+}
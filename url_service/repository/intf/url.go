@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/my_projects/url_service/model"
 )
@@ -10,6 +11,28 @@ type Url interface {
 	Save(ctx context.Context, url *model.Url) error
 	GetByUrl(ctx context.Context, originalUrl string) (*model.Url, error)
 	GetByShortUrl(ctx context.Context, shortUrl string) (*model.Url, error)
+	// GetByShortUrlIgnoringExpiry looks a row up by its short URL the same
+	// way GetByShortUrl does, but returns it even if ExpiresAt has passed.
+	// Admin reads (e.g. GetStats) need this: an expired link should still
+	// report its click count rather than resolving, which is the only thing
+	// expiry is supposed to affect.
+	GetByShortUrlIgnoringExpiry(ctx context.Context, shortUrl string) (*model.Url, error)
 	UpdateClickCount(ctx context.Context, shortUrl string) error
 	List(ctx context.Context, offset, limit int) ([]*model.Url, int64, error)
+	// ListRecentlyAccessed returns up to limit URLs ordered by last_accessed_at
+	// descending, used to replay recent activity to new stream subscribers.
+	ListRecentlyAccessed(ctx context.Context, limit int) ([]*model.Url, error)
+
+	// Delete removes a URL record by its short URL.
+	Delete(ctx context.Context, shortUrl string) error
+	// UpdateShortUrl changes the short code a URL resolves from, used to
+	// rotate a compromised or guessable short code.
+	UpdateShortUrl(ctx context.Context, urlId, newShortUrl string) error
+	// SetExpiry sets (or clears, with a zero time) the expiry of a short URL.
+	SetExpiry(ctx context.Context, shortUrl string, expiresAt time.Time) error
+	// RecordAccess appends an entry to a short URL's access log.
+	RecordAccess(ctx context.Context, shortUrl, referer string) error
+	// RecentAccesses returns up to limit of the most recent access log
+	// entries for a short URL, newest first.
+	RecentAccesses(ctx context.Context, shortUrl string, limit int) ([]model.AccessRecord, error)
 }
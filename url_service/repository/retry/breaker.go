@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState mirrors the classic closed/open/half-open circuit breaker
+// states.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker trips after a run of consecutive failures, short-circuits
+// calls while open, and allows a single trial call through once the cooldown
+// elapses.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	threshold int
+	cooldown  time.Duration
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call may proceed. If the breaker is open but the
+// cooldown has elapsed, it transitions to half-open and allows exactly one
+// trial call through.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// A trial call is already in flight; every other caller is refused
+		// until recordSuccess/recordFailure resolves it.
+		return false
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the breaker and resets the failure count.
+func (b *circuitBreaker) recordSuccess() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// recordFailure increments the failure count and opens the breaker once the
+// threshold is reached (or immediately, if the trial call made during
+// half-open also failed).
+func (b *circuitBreaker) recordFailure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+
+	if !b.allow() {
+		t.Fatal("expected breaker to allow calls while closed")
+	}
+	b.recordFailure()
+	if !b.allow() {
+		t.Fatal("expected breaker to still allow calls below threshold")
+	}
+	b.recordFailure()
+
+	if b.allow() {
+		t.Fatal("expected breaker to reject calls once the failure threshold is reached")
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOnlyOneTrial(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond)
+	b.recordFailure()
+	if b.allow() {
+		t.Fatal("expected breaker to reject calls immediately after opening")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("expected breaker to allow exactly one trial call once the cooldown elapses")
+	}
+	if b.allow() {
+		t.Fatal("expected concurrent callers to be rejected while a half-open trial is in flight")
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond)
+	b.recordFailure()
+	time.Sleep(5 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("expected the trial call to be allowed")
+	}
+	b.recordFailure()
+
+	if b.allow() {
+		t.Fatal("expected breaker to reopen after the half-open trial failed")
+	}
+}
+
+func TestCircuitBreakerHalfOpenSuccessCloses(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond)
+	b.recordFailure()
+	time.Sleep(5 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("expected the trial call to be allowed")
+	}
+	b.recordSuccess()
+
+	if !b.allow() {
+		t.Fatal("expected breaker to be closed (and allow calls) after a successful trial")
+	}
+}
+
+func TestCircuitBreakerDisabledWhenThresholdZero(t *testing.T) {
+	b := newCircuitBreaker(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.recordFailure()
+		if !b.allow() {
+			t.Fatal("expected a zero threshold to disable circuit breaking entirely")
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package retry wraps a repository.Url implementation with exponential
+// backoff retries for transient Postgres failures and a circuit breaker that
+// short-circuits once a backend is clearly down.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/my_projects/url_service/model"
+	repository "github.com/my_projects/url_service/repository/intf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryable Postgres error codes: serialization_failure and deadlock_detected.
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// Config controls the retry and circuit-breaking behavior of a Repository.
+type Config struct {
+	// MaxRetries is the number of additional attempts after the first one.
+	// 0 disables retrying entirely.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles on each
+	// subsequent attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff regardless of attempt count;
+	// jitter of up to 20% is added on top of the capped value.
+	MaxDelay time.Duration
+	// CircuitThreshold is the number of consecutive failures that opens the
+	// breaker. 0 disables circuit breaking.
+	CircuitThreshold int
+	// CircuitCooldown is how long the breaker stays open before allowing a
+	// half-open trial call.
+	CircuitCooldown time.Duration
+}
+
+// DefaultConfig mirrors pester-style clients: a handful of retries with
+// capped exponential backoff, breaker disabled by default.
+func DefaultConfig() Config {
+	return Config{
+		MaxRetries: 3,
+		BaseDelay:  50 * time.Millisecond,
+		MaxDelay:   2 * time.Second,
+	}
+}
+
+// Repository wraps a repository.Url, retrying transient failures with
+// exponential backoff and jitter, and tripping a circuit breaker after too
+// many consecutive failures.
+type Repository struct {
+	next    repository.Url
+	cfg     Config
+	breaker *circuitBreaker
+}
+
+// New wraps next with the retry/circuit-breaking behavior described by cfg.
+func New(next repository.Url, cfg Config) repository.Url {
+	return &Repository{
+		next:    next,
+		cfg:     cfg,
+		breaker: newCircuitBreaker(cfg.CircuitThreshold, cfg.CircuitCooldown),
+	}
+}
+
+func (r *Repository) Save(ctx context.Context, url *model.Url) error {
+	return r.do(ctx, func() error { return r.next.Save(ctx, url) })
+}
+
+func (r *Repository) GetByUrl(ctx context.Context, originalUrl string) (*model.Url, error) {
+	var result *model.Url
+	err := r.do(ctx, func() error {
+		var err error
+		result, err = r.next.GetByUrl(ctx, originalUrl)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) GetByShortUrl(ctx context.Context, shortUrl string) (*model.Url, error) {
+	var result *model.Url
+	err := r.do(ctx, func() error {
+		var err error
+		result, err = r.next.GetByShortUrl(ctx, shortUrl)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) GetByShortUrlIgnoringExpiry(ctx context.Context, shortUrl string) (*model.Url, error) {
+	var result *model.Url
+	err := r.do(ctx, func() error {
+		var err error
+		result, err = r.next.GetByShortUrlIgnoringExpiry(ctx, shortUrl)
+		return err
+	})
+	return result, err
+}
+
+func (r *Repository) UpdateClickCount(ctx context.Context, shortUrl string) error {
+	return r.do(ctx, func() error { return r.next.UpdateClickCount(ctx, shortUrl) })
+}
+
+func (r *Repository) List(ctx context.Context, offset, limit int) ([]*model.Url, int64, error) {
+	var (
+		urls  []*model.Url
+		total int64
+	)
+	err := r.do(ctx, func() error {
+		var err error
+		urls, total, err = r.next.List(ctx, offset, limit)
+		return err
+	})
+	return urls, total, err
+}
+
+func (r *Repository) ListRecentlyAccessed(ctx context.Context, limit int) ([]*model.Url, error) {
+	var urls []*model.Url
+	err := r.do(ctx, func() error {
+		var err error
+		urls, err = r.next.ListRecentlyAccessed(ctx, limit)
+		return err
+	})
+	return urls, err
+}
+
+func (r *Repository) Delete(ctx context.Context, shortUrl string) error {
+	return r.do(ctx, func() error { return r.next.Delete(ctx, shortUrl) })
+}
+
+func (r *Repository) UpdateShortUrl(ctx context.Context, urlId, newShortUrl string) error {
+	return r.do(ctx, func() error { return r.next.UpdateShortUrl(ctx, urlId, newShortUrl) })
+}
+
+func (r *Repository) SetExpiry(ctx context.Context, shortUrl string, expiresAt time.Time) error {
+	return r.do(ctx, func() error { return r.next.SetExpiry(ctx, shortUrl, expiresAt) })
+}
+
+func (r *Repository) RecordAccess(ctx context.Context, shortUrl, referer string) error {
+	return r.do(ctx, func() error { return r.next.RecordAccess(ctx, shortUrl, referer) })
+}
+
+func (r *Repository) RecentAccesses(ctx context.Context, shortUrl string, limit int) ([]model.AccessRecord, error) {
+	var records []model.AccessRecord
+	err := r.do(ctx, func() error {
+		var err error
+		records, err = r.next.RecentAccesses(ctx, shortUrl, limit)
+		return err
+	})
+	return records, err
+}
+
+// do runs op, retrying it with exponential backoff and jitter while the
+// error is retryable, and refusing to run it at all while the circuit
+// breaker is open.
+func (r *Repository) do(ctx context.Context, op func() error) error {
+	if !r.breaker.allow() {
+		return status.Error(codes.Unavailable, "repository circuit breaker is open")
+	}
+
+	var err error
+	for attempt := 0; attempt <= r.cfg.MaxRetries; attempt++ {
+		err = op()
+		if err == nil {
+			r.breaker.recordSuccess()
+			return nil
+		}
+
+		if !isRetryable(err) {
+			r.breaker.recordFailure()
+			return err
+		}
+
+		if attempt == r.cfg.MaxRetries {
+			break
+		}
+
+		delay := backoff(r.cfg.BaseDelay, r.cfg.MaxDelay, attempt)
+		slog.Error("retrying repository operation", "err", err, "attempt", attempt+1, "delay", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			r.breaker.recordFailure()
+			return ctx.Err()
+		}
+	}
+
+	r.breaker.recordFailure()
+	return err
+}
+
+// isRetryable reports whether err represents a transient Postgres failure
+// worth retrying. pgx.ErrNoRows and constraint violations (23xxx) must pass
+// through immediately.
+func isRetryable(err error) bool {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if errors.Is(err, pgx.ErrTxClosed) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgSerializationFailure, pgDeadlockDetected:
+			return true
+		}
+		if len(pgErr.Code) > 0 && pgErr.Code[0] == '2' {
+			// constraint violations and similar (23xxx) are not transient
+			return false
+		}
+	}
+
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		return true
+	}
+
+	// A live connection dropping mid-query (connection reset, broken pipe,
+	// unexpected EOF) doesn't surface as pgconn.ConnectError — that's only
+	// for a failed initial dial. pgconn.SafeToRetry classifies the rest.
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	return false
+}
+
+// backoff returns an exponential delay for the given attempt, capped at
+// maxDelay, plus up to 20% jitter on top (so at most 1.2x maxDelay).
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base << attempt
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay + jitter
+}
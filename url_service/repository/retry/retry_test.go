@@ -0,0 +1,41 @@
+package retry
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// safeRetryErr simulates a driver-level network error that implements the
+// unexported interface pgconn.SafeToRetry checks for (e.g. a wrapped
+// net.OpError/io.ErrUnexpectedEOF on a connection that dropped mid-query).
+type safeRetryErr struct{ safe bool }
+
+func (e *safeRetryErr) Error() string     { return "connection reset by peer" }
+func (e *safeRetryErr) SafeToRetry() bool { return e.safe }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no rows is not retryable", pgx.ErrNoRows, false},
+		{"closed transaction is retryable", pgx.ErrTxClosed, true},
+		{"serialization failure is retryable", &pgconn.PgError{Code: pgSerializationFailure}, true},
+		{"deadlock detected is retryable", &pgconn.PgError{Code: pgDeadlockDetected}, true},
+		{"constraint violation is not retryable", &pgconn.PgError{Code: "23505"}, false},
+		{"failed initial connect is retryable", &pgconn.ConnectError{}, true},
+		{"mid-query connection reset is retryable", &safeRetryErr{safe: true}, true},
+		{"non-network error is not retryable", &safeRetryErr{safe: false}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
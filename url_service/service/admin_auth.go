@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminAuthInterceptor rejects any UrlAdminService call that doesn't carry
+// a "Bearer <token>" authorization header matching the configured shared
+// secret.
+func AdminAuthInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+		}
+
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "missing authorization header")
+		}
+
+		const prefix = "Bearer "
+		presented := values[0]
+		if len(presented) <= len(prefix) || presented[:len(prefix)] != prefix {
+			return nil, status.Error(codes.Unauthenticated, "authorization header must be a bearer token")
+		}
+
+		presentedToken := presented[len(prefix):]
+		if subtle.ConstantTimeCompare([]byte(presentedToken), []byte(token)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid admin token")
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminAuthInterceptor(t *testing.T) {
+	const wantToken = "s3cr3t"
+
+	okHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "handled", nil
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode codes.Code
+	}{
+		{
+			name:     "missing metadata",
+			ctx:      context.Background(),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "metadata without authorization header",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.MD{}),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "authorization header missing Bearer prefix",
+			ctx:      ctxWithAuth("s3cr3t"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "authorization header is just the Bearer prefix",
+			ctx:      ctxWithAuth("Bearer "),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong token",
+			ctx:      ctxWithAuth("Bearer wrong-token"),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "correct token",
+			ctx:      ctxWithAuth("Bearer " + wantToken),
+			wantCode: codes.OK,
+		},
+	}
+
+	interceptor := AdminAuthInterceptor(wantToken)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := interceptor(tt.ctx, nil, &grpc.UnaryServerInfo{}, okHandler)
+
+			if tt.wantCode == codes.OK {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if resp != "handled" {
+					t.Fatalf("handler was not invoked, got resp %v", resp)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error with code %v, got nil", tt.wantCode)
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("status.Code(err) = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func ctxWithAuth(value string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"github.com/my_projects/url_service/gen"
+	repository "github.com/my_projects/url_service/repository/intf"
+)
+
+const defaultRecentAccessLimit = 10
+
+// UrlAdminServiceImpl serves UrlAdminService: the management surface on top
+// of the same repository the public UrlService uses. It is registered on a
+// separate listener guarded by AdminAuthInterceptor.
+type UrlAdminServiceImpl struct {
+	gen.UnimplementedUrlAdminServiceServer
+	repo repository.Url
+}
+
+func NewUrlAdminServiceImpl(repo repository.Url) *UrlAdminServiceImpl {
+	return &UrlAdminServiceImpl{repo: repo}
+}
+
+// ListUrls returns a paginated list of all shortened URLs.
+func (a *UrlAdminServiceImpl) ListUrls(ctx context.Context, req *gen.ListUrlsRequest) (*gen.ListUrlsResponse, error) {
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	offset := int((page - 1) * pageSize)
+	limit := int(pageSize)
+
+	urls, totalCount, err := a.repo.List(ctx, offset, limit)
+	if err != nil {
+		slog.Error("error while listing URLs", "err", err, "page", page, "page_size", pageSize)
+		return nil, fromRepoErr(err, "no urls found")
+	}
+
+	var urlInfos []*gen.UrlInfo
+	for _, url := range urls {
+		lastAccessed := ""
+		if !url.LastAccessedAt.IsZero() {
+			lastAccessed = url.LastAccessedAt.Format(time.RFC3339)
+		}
+		expiresAt := ""
+		if url.ExpiresAt != nil {
+			expiresAt = url.ExpiresAt.Format(time.RFC3339)
+		}
+
+		urlInfos = append(urlInfos, &gen.UrlInfo{
+			UrlId:          url.UrlId,
+			OriginalUrl:    url.Url,
+			ShortUrl:       url.ShortUrl,
+			ClickCount:     url.ClickCount,
+			CreatedAt:      url.CreatedAt.Format(time.RFC3339),
+			LastAccessedAt: lastAccessed,
+			ExpiresAt:      expiresAt,
+		})
+	}
+
+	return &gen.ListUrlsResponse{
+		Urls:       urlInfos,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+	}, nil
+}
+
+// DeleteUrl removes a short URL.
+func (a *UrlAdminServiceImpl) DeleteUrl(ctx context.Context, req *gen.DeleteUrlRequest) (*gen.DeleteUrlResponse, error) {
+	if req.ShortUrl == "" {
+		return nil, invalidArgument("short URL cannot be empty")
+	}
+
+	if err := a.repo.Delete(ctx, req.ShortUrl); err != nil {
+		slog.Error("error while deleting url", "err", err, "short_url", req.ShortUrl)
+		return nil, fromRepoErr(err, "short URL not found")
+	}
+
+	return &gen.DeleteUrlResponse{}, nil
+}
+
+// RotateShortCode replaces a URL's short code with a freshly generated one.
+func (a *UrlAdminServiceImpl) RotateShortCode(ctx context.Context, req *gen.RotateShortCodeRequest) (*gen.RotateShortCodeResponse, error) {
+	if req.UrlId == "" {
+		return nil, invalidArgument("url_id cannot be empty")
+	}
+
+	newShortUrl := generateShortCode(fmt.Sprintf("%s:%d", req.UrlId, time.Now().UnixNano()))
+
+	if err := a.repo.UpdateShortUrl(ctx, req.UrlId, newShortUrl); err != nil {
+		slog.Error("error while rotating short code", "err", err, "url_id", req.UrlId)
+		return nil, fromRepoErr(err, "url not found")
+	}
+
+	return &gen.RotateShortCodeResponse{NewShortUrl: newShortUrl}, nil
+}
+
+// SetExpiry sets, or with an empty expires_at clears, a short URL's expiry.
+func (a *UrlAdminServiceImpl) SetExpiry(ctx context.Context, req *gen.SetExpiryRequest) (*gen.SetExpiryResponse, error) {
+	if req.ShortUrl == "" {
+		return nil, invalidArgument("short URL cannot be empty")
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, invalidArgument("expires_at must be an RFC 3339 timestamp")
+		}
+		expiresAt = parsed
+	}
+
+	if err := a.repo.SetExpiry(ctx, req.ShortUrl, expiresAt); err != nil {
+		slog.Error("error while setting expiry", "err", err, "short_url", req.ShortUrl)
+		return nil, fromRepoErr(err, "short URL not found")
+	}
+
+	return &gen.SetExpiryResponse{}, nil
+}
+
+// GetStats returns a short URL's click count and its most recent accesses.
+func (a *UrlAdminServiceImpl) GetStats(ctx context.Context, req *gen.GetStatsRequest) (*gen.GetStatsResponse, error) {
+	if req.ShortUrl == "" {
+		return nil, invalidArgument("short URL cannot be empty")
+	}
+
+	// Use the expiry-ignoring lookup: an admin auditing or just-expired link
+	// still wants its click count, not a NotFound that disagrees with ListUrls.
+	url, err := a.repo.GetByShortUrlIgnoringExpiry(ctx, req.ShortUrl)
+	if err != nil {
+		slog.Error("error while fetching url for stats", "err", err, "short_url", req.ShortUrl)
+		return nil, fromRepoErr(err, "short URL not found")
+	}
+
+	limit := int(req.RecentLimit)
+	if limit <= 0 {
+		limit = defaultRecentAccessLimit
+	}
+
+	accesses, err := a.repo.RecentAccesses(ctx, req.ShortUrl, limit)
+	if err != nil {
+		slog.Error("error while fetching recent accesses", "err", err, "short_url", req.ShortUrl)
+		return nil, fromRepoErr(err, "short URL not found")
+	}
+
+	recent := make([]*gen.AccessRecord, 0, len(accesses))
+	for _, access := range accesses {
+		recent = append(recent, &gen.AccessRecord{
+			Timestamp: access.Timestamp.Format(time.RFC3339),
+			Referer:   access.Referer,
+		})
+	}
+
+	return &gen.GetStatsResponse{
+		ClickCount:     url.ClickCount,
+		RecentAccesses: recent,
+	}, nil
+}
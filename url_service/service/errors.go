@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// postgres error codes we translate into a specific gRPC status rather than
+// falling back to codes.Internal.
+const pgUniqueViolation = "23505"
+
+// invalidArgument wraps a user-input validation failure (e.g. util.ValidateURL
+// or an empty required field) as codes.InvalidArgument.
+func invalidArgument(msg string) error {
+	return status.Error(codes.InvalidArgument, msg)
+}
+
+// fromRepoErr maps a repository error into the gRPC status a caller should
+// see: codes.NotFound for a missing row, codes.AlreadyExists for a uniqueness
+// violation, and codes.Internal for everything else (true backend failures).
+// A codes.Unavailable status (the repository circuit breaker being open) is
+// passed through unchanged. err must be non-nil.
+func fromRepoErr(err error, notFoundMsg string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Error(codes.NotFound, notFoundMsg)
+	}
+
+	if st, ok := status.FromError(err); ok && st.Code() == codes.Unavailable {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return status.Error(codes.AlreadyExists, "resource already exists")
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// gatewayErrorBody is the stable JSON shape every gateway error response
+// renders, regardless of which RPC produced it.
+type gatewayErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// httpStatusFromCode maps a gRPC status code to the HTTP status the gateway
+// should respond with.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GatewayErrorHandler renders a gRPC status as a stable {code, message,
+// details} JSON body with the HTTP status it maps to, instead of the
+// gateway's default behaviour of surfacing every error as a 500.
+func GatewayErrorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, _ *http.Request, err error) {
+	st := status.Convert(err)
+	httpStatus := httpStatusFromCode(st.Code())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+
+	body := gatewayErrorBody{
+		Code:    httpStatus,
+		Message: st.Message(),
+	}
+	if len(st.Details()) > 0 {
+		if details, marshalErr := json.Marshal(st.Details()); marshalErr == nil {
+			body.Details = string(details)
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -13,44 +13,56 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/my_projects/url_service/gen"
 	"github.com/my_projects/url_service/model"
+	"github.com/my_projects/url_service/pubsub"
 	repository "github.com/my_projects/url_service/repository/intf"
 	"github.com/my_projects/url_service/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type URLServiceImpl struct {
 	gen.UnimplementedUrlServiceServer
 	repo repository.Url
+	hub  *pubsub.ClickHub
 }
 
-func NewURLServiceImpl(repo repository.Url) *URLServiceImpl {
+func NewURLServiceImpl(repo repository.Url, hub *pubsub.ClickHub) *URLServiceImpl {
 	return &URLServiceImpl{
 		repo: repo,
+		hub:  hub,
 	}
 }
 
 func (u *URLServiceImpl) Shorten(ctx context.Context, req *gen.ShortenUrlRequest) (*gen.ShortenUrlResponse, error) {
 	if err := util.ValidateURL(req.Url); err != nil {
 		slog.Error("invalid URL provided", "err", err, "url", req.Url)
-		return nil, err
+		return nil, invalidArgument(err.Error())
 	}
 
 	existingUrl, err := u.repo.GetByUrl(ctx, req.Url)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		slog.Error("error while fetching existing url", "err", err, "url", req.Url)
-		return nil, errors.New("failed to check existing URL")
+		return nil, fromRepoErr(err, "url not found")
 	}
 
-	// if url already exists return
-	if existingUrl != nil {
+	// if url already exists and its short code hasn't expired, return it.
+	// An expired match must not be handed back as if it still resolved, so
+	// fall through and mint a fresh short code instead.
+	if existingUrl != nil && (existingUrl.ExpiresAt == nil || existingUrl.ExpiresAt.After(time.Now())) {
 		return &gen.ShortenUrlResponse{
 			UrlId:    existingUrl.UrlId,
 			ShortUrl: existingUrl.ShortUrl,
 		}, nil
 	}
 
-	// Generate short code from URL (max 10 characters)
-	shortCode := generateShortCode(req.Url)
+	// Generate short code (max 10 characters). Salt with urlId rather than
+	// hashing the URL alone, so re-minting after an expired match (above)
+	// produces a different code instead of colliding with the expired row
+	// on the short_url unique constraint.
 	urlId := fmt.Sprintf("url_%d", time.Now().UnixNano())
+	shortCode := generateShortCode(fmt.Sprintf("%s:%s", req.Url, urlId))
 
 	// Create URL model
 	urlModel := &model.Url{
@@ -65,7 +77,7 @@ func (u *URLServiceImpl) Shorten(ctx context.Context, req *gen.ShortenUrlRequest
 	err = u.repo.Save(ctx, urlModel)
 	if err != nil {
 		slog.Error("error while inserting url", "err", err, "url_id", urlId)
-		return nil, errors.New("failed to save URL")
+		return nil, fromRepoErr(err, "url not found")
 	}
 
 	return &gen.ShortenUrlResponse{
@@ -78,7 +90,7 @@ func (u *URLServiceImpl) Shorten(ctx context.Context, req *gen.ShortenUrlRequest
 func (u *URLServiceImpl) Expand(ctx context.Context, req *gen.ExpandUrlRequest) (*gen.ExpandUrlResponse, error) {
 	if req.ShortUrl == "" {
 		slog.Error("empty short URL provided")
-		return nil, errors.New("short URL cannot be empty")
+		return nil, invalidArgument("short URL cannot be empty")
 	}
 
 	// Get URL by short code
@@ -86,18 +98,35 @@ func (u *URLServiceImpl) Expand(ctx context.Context, req *gen.ExpandUrlRequest)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			slog.Error("short URL not found", "short_url", req.ShortUrl)
-			return nil, errors.New("short URL not found")
+		} else {
+			slog.Error("error while fetching URL by short code", "err", err, "short_url", req.ShortUrl)
 		}
-		slog.Error("error while fetching URL by short code", "err", err, "short_url", req.ShortUrl)
-		return nil, errors.New("failed to fetch URL")
+		return nil, fromRepoErr(err, "short URL not found")
 	}
 
-	// Update click count asynchronously (fire and forget to keep latency low)
+	referer := refererFromContext(ctx)
+
+	// Update click count and the access log asynchronously (fire and forget
+	// to keep latency low).
 	go func() {
 		if err := u.repo.UpdateClickCount(context.Background(), req.ShortUrl); err != nil {
 			slog.Error("failed to update click count", "err", err, "short_url", req.ShortUrl)
 		}
 	}()
+	go func() {
+		if err := u.repo.RecordAccess(context.Background(), req.ShortUrl, referer); err != nil {
+			slog.Error("failed to record access", "err", err, "short_url", req.ShortUrl)
+		}
+	}()
+
+	u.hub.Publish(&gen.ClickEvent{
+		UrlId:       urlData.UrlId,
+		ShortUrl:    urlData.ShortUrl,
+		OriginalUrl: urlData.Url,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Referer:     referer,
+		IpHash:      ipHashFromContext(ctx),
+	})
 
 	return &gen.ExpandUrlResponse{
 		OriginalUrl: urlData.Url,
@@ -106,55 +135,108 @@ func (u *URLServiceImpl) Expand(ctx context.Context, req *gen.ExpandUrlRequest)
 	}, nil
 }
 
-// ListUrls returns a paginated list of all shortened URLs (admin function)
-func (u *URLServiceImpl) ListUrls(ctx context.Context, req *gen.ListUrlsRequest) (*gen.ListUrlsResponse, error) {
-	// Set default pagination values
-	page := req.Page
-	if page < 1 {
-		page = 1
-	}
-	pageSize := req.PageSize
-	if pageSize < 1 {
-		pageSize = 10
+// WatchClicks streams ClickEvents as Expand resolves short URLs, optionally
+// replaying recently accessed URLs first and filtering by short_url/url_id.
+func (u *URLServiceImpl) WatchClicks(req *gen.WatchClicksRequest, stream gen.UrlService_WatchClicksServer) error {
+	matches := func(e *gen.ClickEvent) bool {
+		if req.ShortUrl != "" && e.ShortUrl != req.ShortUrl {
+			return false
+		}
+		if req.UrlId != "" && e.UrlId != req.UrlId {
+			return false
+		}
+		return true
 	}
-	if pageSize > 100 {
-		pageSize = 100 
+
+	if req.ReplayCount > 0 {
+		recent, err := u.repo.ListRecentlyAccessed(stream.Context(), int(req.ReplayCount))
+		if err != nil {
+			slog.Error("error while loading replay window", "err", err)
+			return status.Error(codes.Internal, "failed to load replay window")
+		}
+		for _, url := range recent {
+			event := &gen.ClickEvent{
+				UrlId:       url.UrlId,
+				ShortUrl:    url.ShortUrl,
+				OriginalUrl: url.Url,
+				Timestamp:   url.LastAccessedAt.Format(time.RFC3339),
+			}
+			if !matches(event) {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
 	}
 
-	offset := int((page - 1) * pageSize)
-	limit := int(pageSize)
+	events, unsubscribe, droppedCount := u.hub.Subscribe()
+	defer func() {
+		unsubscribe()
+		if dropped := droppedCount(); dropped > 0 {
+			slog.Warn("watch clicks subscriber fell behind", "dropped", dropped)
+		}
+	}()
 
-	// Get paginated URLs
-	urls, totalCount, err := u.repo.List(ctx, offset, limit)
-	if err != nil {
-		slog.Error("error while listing URLs", "err", err, "page", page, "page_size", pageSize)
-		return nil, errors.New("failed to list URLs")
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !matches(event) {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
 	}
+}
 
-	// Convert to response format
-	var urlInfos []*gen.UrlInfo
-	for _, url := range urls {
-		lastAccessed := ""
-		if !url.LastAccessedAt.IsZero() {
-			lastAccessed = url.LastAccessedAt.Format(time.RFC3339)
-		}
+// refererFromContext extracts the Referer header forwarded by the gateway,
+// if any. grpc-gateway forwards ordinary HTTP headers prefixed with
+// "grpcgateway-" (only a short allowlist passes through unprefixed), so that
+// key is checked first; the bare key remains a fallback for direct gRPC
+// callers that set it themselves.
+func refererFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("grpcgateway-referer"); len(values) > 0 {
+		return values[0]
+	}
+	if values := md.Get("referer"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
 
-		urlInfos = append(urlInfos, &gen.UrlInfo{
-			UrlId:          url.UrlId,
-			OriginalUrl:    url.Url,
-			ShortUrl:       url.ShortUrl,
-			ClickCount:     url.ClickCount,
-			CreatedAt:      url.CreatedAt.Format(time.RFC3339),
-			LastAccessedAt: lastAccessed,
-		})
-	}
-
-	return &gen.ListUrlsResponse{
-		Urls:       urlInfos,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+// ipHashFromContext hashes the caller's address so click events never carry
+// a raw client IP. Over the gateway path, peer.FromContext would see the
+// gateway's own loopback dial rather than the browser, so the real address
+// forwarded by http.clientAddrAnnotator as metadata is preferred; the gRPC
+// peer remains a fallback for direct gRPC callers.
+func ipHashFromContext(ctx context.Context) string {
+	addr := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// Must match http.clientAddrMetadataKey ("x-client-addr").
+		if values := md.Get("x-client-addr"); len(values) > 0 {
+			addr = values[0]
+		}
+	}
+	if addr == "" {
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return ""
+		}
+		addr = p.Addr.String()
+	}
+	sum := sha256.Sum256([]byte(addr))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
 // generateShortCode creates a unique short code from a URL (max 10 characters)
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/my_projects/url_service/gen"
+	"github.com/my_projects/url_service/model"
+	"github.com/my_projects/url_service/pubsub"
+	repository "github.com/my_projects/url_service/repository/intf"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUrlRepo is an in-memory repository.Url that enforces the same
+// UNIQUE(short_url) constraint the real schema does, so Shorten's
+// short-code collisions surface the way they would against Postgres.
+type fakeUrlRepo struct {
+	byUrl      map[string]*model.Url
+	byShortUrl map[string]*model.Url
+}
+
+func newFakeUrlRepo() *fakeUrlRepo {
+	return &fakeUrlRepo{
+		byUrl:      make(map[string]*model.Url),
+		byShortUrl: make(map[string]*model.Url),
+	}
+}
+
+func (f *fakeUrlRepo) Save(_ context.Context, url *model.Url) error {
+	if _, exists := f.byShortUrl[url.ShortUrl]; exists {
+		return &pgconn.PgError{Code: pgUniqueViolation}
+	}
+	cp := *url
+	f.byUrl[url.Url] = &cp
+	f.byShortUrl[url.ShortUrl] = &cp
+	return nil
+}
+
+func (f *fakeUrlRepo) GetByUrl(_ context.Context, originalUrl string) (*model.Url, error) {
+	url, ok := f.byUrl[originalUrl]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return url, nil
+}
+
+func (f *fakeUrlRepo) GetByShortUrl(_ context.Context, shortUrl string) (*model.Url, error) {
+	url, ok := f.byShortUrl[shortUrl]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if url.ExpiresAt != nil && url.ExpiresAt.Before(time.Now()) {
+		return nil, pgx.ErrNoRows
+	}
+	return url, nil
+}
+
+func (f *fakeUrlRepo) GetByShortUrlIgnoringExpiry(_ context.Context, shortUrl string) (*model.Url, error) {
+	url, ok := f.byShortUrl[shortUrl]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return url, nil
+}
+
+func (f *fakeUrlRepo) UpdateClickCount(context.Context, string) error { return nil }
+
+func (f *fakeUrlRepo) List(context.Context, int, int) ([]*model.Url, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeUrlRepo) ListRecentlyAccessed(context.Context, int) ([]*model.Url, error) {
+	return nil, nil
+}
+
+func (f *fakeUrlRepo) Delete(context.Context, string) error { return nil }
+
+func (f *fakeUrlRepo) UpdateShortUrl(context.Context, string, string) error { return nil }
+
+func (f *fakeUrlRepo) SetExpiry(_ context.Context, shortUrl string, expiresAt time.Time) error {
+	url, ok := f.byShortUrl[shortUrl]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	if expiresAt.IsZero() {
+		url.ExpiresAt = nil
+		return nil
+	}
+	url.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (f *fakeUrlRepo) RecordAccess(context.Context, string, string) error { return nil }
+
+func (f *fakeUrlRepo) RecentAccesses(context.Context, string, int) ([]model.AccessRecord, error) {
+	return nil, nil
+}
+
+var _ repository.Url = (*fakeUrlRepo)(nil)
+
+func TestShorten_RemintsAfterExpiry(t *testing.T) {
+	repo := newFakeUrlRepo()
+	svc := NewURLServiceImpl(repo, pubsub.NewClickHub(0))
+	ctx := context.Background()
+	const rawUrl = "https://example.com/some/long/path"
+
+	first, err := svc.Shorten(ctx, &gen.ShortenUrlRequest{Url: rawUrl})
+	if err != nil {
+		t.Fatalf("first Shorten: %v", err)
+	}
+
+	if err := repo.SetExpiry(ctx, first.ShortUrl, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("expiring first short url: %v", err)
+	}
+
+	second, err := svc.Shorten(ctx, &gen.ShortenUrlRequest{Url: rawUrl})
+	if err != nil {
+		t.Fatalf("second Shorten after expiry: %v (want a fresh code, not %v)", err, status.Code(err))
+	}
+	if second.ShortUrl == first.ShortUrl {
+		t.Fatalf("second Shorten returned the same short_url %q as the expired row; expected a new code", second.ShortUrl)
+	}
+	if second.UrlId == first.UrlId {
+		t.Fatalf("second Shorten returned the same url_id %q as the expired row; expected a new row", second.UrlId)
+	}
+}
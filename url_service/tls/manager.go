@@ -0,0 +1,338 @@
+// Package tls loads and serves TLS certificates for the gRPC server and HTTP
+// gateway, either from a watched directory pair or, for local development,
+// from an in-memory self-signed CA.
+package tls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	leafValidity = 365 * 24 * time.Hour
+	// debounce coalesces bursts of filesystem events (e.g. a cert+key pair
+	// written as two separate writes) into a single reload.
+	debounce = 500 * time.Millisecond
+)
+
+// Manager serves *tls.Certificate by server name, reloading from disk when
+// the cert/key directories change, or regenerating a self-signed leaf when
+// it approaches expiry.
+type Manager struct {
+	certDir    string
+	keyDir     string
+	selfSigned bool
+
+	certs       sync.Map // serverName -> *tls.Certificate
+	defaultCert sync.Map // single-entry holder for the "no SNI match" fallback
+
+	defaultMu         sync.Mutex
+	defaultServerName string // server name currently backing defaultCert, so reload can keep it fresh
+
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	cancelWatch context.CancelFunc
+}
+
+// NewManager builds a Manager. When selfSigned is true it generates an
+// in-memory CA and leaf immediately and ignores certDir/keyDir; otherwise it
+// loads every cert/key pair found in those directories. Watching them for
+// changes begins only once Start is called.
+func NewManager(certDir, keyDir string, selfSigned bool) (*Manager, error) {
+	m := &Manager{certDir: certDir, keyDir: keyDir, selfSigned: selfSigned}
+
+	if selfSigned {
+		if err := m.generateSelfSigned(); err != nil {
+			return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		return m, nil
+	}
+
+	if err := m.reload(); err != nil {
+		return nil, fmt.Errorf("failed to load certificates from %s: %w", certDir, err)
+	}
+
+	return m, nil
+}
+
+// GetCertificate implements the tls.Config.GetCertificate callback, selecting
+// a certificate by SNI server name and falling back to the default/self-signed
+// certificate when there is no exact match.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello.ServerName != "" {
+		if cert, ok := m.certs.Load(hello.ServerName); ok {
+			return cert.(*tls.Certificate), nil
+		}
+	}
+
+	if m.selfSigned && m.certExpiringSoon() {
+		if err := m.generateSelfSigned(); err != nil {
+			slog.Error("failed to re-issue expiring self-signed certificate", "err", err)
+		}
+	}
+
+	if cert, ok := m.defaultCert.Load("default"); ok {
+		return cert.(*tls.Certificate), nil
+	}
+
+	return nil, fmt.Errorf("no certificate available for server name %q", hello.ServerName)
+}
+
+// Name identifies the Manager as an internal/app.Component.
+func (m *Manager) Name() string { return "tls-certificates" }
+
+// Start launches the directory watcher in the background (a no-op in
+// self-signed mode) and returns immediately.
+func (m *Manager) Start(_ context.Context) error {
+	watchCtx, cancel := context.WithCancel(context.Background())
+	m.cancelWatch = cancel
+
+	go func() {
+		if err := m.Watch(watchCtx); err != nil {
+			slog.Error("certificate watcher stopped", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop cancels the background directory watcher.
+func (m *Manager) Stop(_ context.Context) error {
+	if m.cancelWatch != nil {
+		m.cancelWatch()
+	}
+	return nil
+}
+
+// Watch starts watching certDir/keyDir for changes and recompiles the
+// lookup table on every debounced burst of events. It blocks until ctx is
+// cancelled. It is a no-op in self-signed mode.
+func (m *Manager) Watch(ctx context.Context) error {
+	if m.selfSigned {
+		<-ctx.Done()
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, dir := range []string{m.certDir, m.keyDir} {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	var timer *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() {
+					if err := m.reload(); err != nil {
+						slog.Error("failed to reload certificates", "err", err)
+					}
+				})
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Error("fsnotify watcher error", "err", err)
+		}
+	}
+}
+
+// reload reads every *.crt/*.key pair in certDir/keyDir, keyed by file name
+// (sans extension) treated as the server name, and atomically replaces the
+// lookup table.
+func (m *Manager) reload() error {
+	entries, err := os.ReadDir(m.certDir)
+	if err != nil {
+		return err
+	}
+
+	fresh := make(map[string]*tls.Certificate)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".crt" {
+			continue
+		}
+		serverName := entry.Name()[:len(entry.Name())-len(filepath.Ext(entry.Name()))]
+		certPath := filepath.Join(m.certDir, entry.Name())
+		keyPath := filepath.Join(m.keyDir, serverName+".key")
+
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			slog.Error("failed to load certificate pair", "err", err, "server_name", serverName)
+			continue
+		}
+		fresh[serverName] = &cert
+	}
+
+	m.defaultMu.Lock()
+	defer m.defaultMu.Unlock()
+
+	// Drop server names whose cert/key pair is no longer on disk, so a
+	// revoked or renamed hostname stops being served over SNI.
+	m.certs.Range(func(key, _ any) bool {
+		serverName := key.(string)
+		if _, ok := fresh[serverName]; !ok {
+			m.certs.Delete(serverName)
+		}
+		return true
+	})
+
+	for serverName, cert := range fresh {
+		m.certs.Store(serverName, cert)
+		if m.defaultServerName == "" {
+			// First reload: nothing backs the fallback yet, so pick this one.
+			m.defaultServerName = serverName
+		}
+		if serverName == m.defaultServerName {
+			// Keep the fallback in sync with whichever server name backs it,
+			// instead of pinning it to whatever cert happened to load first.
+			m.defaultCert.Store("default", cert)
+		}
+	}
+
+	if _, ok := fresh[m.defaultServerName]; m.defaultServerName != "" && !ok {
+		// The server name backing the fallback disappeared too; repoint it
+		// at whatever is left, or clear it if nothing is.
+		m.defaultServerName = ""
+		m.defaultCert.Delete("default")
+		for serverName, cert := range fresh {
+			m.defaultServerName = serverName
+			m.defaultCert.Store("default", cert)
+			break
+		}
+	}
+
+	return nil
+}
+
+// generateSelfSigned creates the in-memory CA on first use (reusing it on
+// later calls), issues a fresh server leaf signed by it, and installs the
+// leaf as the default certificate.
+func (m *Manager) generateSelfSigned() error {
+	if m.caKey == nil || m.caCert == nil {
+		caKey, caCert, err := generateCA()
+		if err != nil {
+			return err
+		}
+		m.caKey, m.caCert = caKey, caCert
+	}
+
+	leaf, err := generateLeaf(m.caKey, m.caCert)
+	if err != nil {
+		return err
+	}
+
+	m.defaultCert.Store("default", leaf)
+	return nil
+}
+
+// certExpiringSoon reports whether the current default certificate is within
+// one day of its NotAfter, meaning it should be re-issued.
+func (m *Manager) certExpiringSoon() bool {
+	cert, ok := m.defaultCert.Load("default")
+	if !ok {
+		return true
+	}
+	leaf, err := x509.ParseCertificate(cert.(*tls.Certificate).Certificate[0])
+	if err != nil {
+		return true
+	}
+	return time.Until(leaf.NotAfter) < 24*time.Hour
+}
+
+func generateCA() (*ecdsa.PrivateKey, *x509.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "url_service self-signed CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, cert, nil
+}
+
+func generateLeaf(caKey *ecdsa.PrivateKey, caCert *x509.Certificate) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der, caCert.Raw},
+		PrivateKey:  key,
+	}, nil
+}